@@ -1,9 +1,11 @@
 package BigBFT
 
 import (
+	"errors"
 	"math"
 	"math/rand"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/salemmohammed/BigBFT/log"
@@ -12,9 +14,22 @@ import (
 // DB is general interface implemented by client to call client library
 type DB interface {
 	Init() error
+	Read(key int) ([]byte, error)
 	Write(key int, value []byte,Globalcounter int) error
 	Stop() error
 }
+
+// Deleter is an optional interface a DB implementation may satisfy to
+// participate in delete benchmarking. NewBenchmark refuses to start with
+// DeletePercentage > 0 against a DB that doesn't implement it.
+type Deleter interface {
+	Delete(key int) error
+}
+
+// errNoDeleter is the error worker records and logs for a delete, instead
+// of fabricating a zero-latency success, when db doesn't implement Deleter.
+// NewBenchmark already fails fast on this, so it should be unreachable.
+var errNoDeleter = errors.New("delete requested but DB does not implement Deleter")
 // Bconfig holds all benchmark configuration
 type Bconfig struct {
 	T                    int     // total number of running time in seconds
@@ -25,6 +40,19 @@ type Bconfig struct {
 	Concurrency          int     // number of simulated clients
 	Distribution         string  // distribution
 	LinearizabilityCheck bool    // run linearizability checker at the end of benchmark
+	DeletePercentage     float64 // fraction (0-1) of all operations that are deletes, taken from the share left after W
+	SequentialRead       bool    // reads walk the keys written during Load() in order instead of using Distribution
+	BenchMode            bool    // suppress everything but the Go-benchmark-format percentile report; set in config only, there is no command-line flag for it
+	Name                 string  // label used as the Benchmark<Name> prefix when BenchMode is set
+	Workload             string  // name of a registered Workload to dispatch ops; empty keeps the W/DeletePercentage ratios
+	Seed                 int64   // base seed each worker's rand is derived from; 0 picks a random one and logs it
+
+	CPUProfile     string        // pprof CPU profile output path, unused if empty
+	MemProfile     string        // pprof heap profile output path, written at completion, unused if empty
+	TraceFile      string        // runtime/trace output path, unused if empty
+	MaxCPU         int           // GOMAXPROCS to apply for the run, unused if 0
+	SampleInterval time.Duration // how often to sample throughput/latency into SampleFile, unused if 0
+	SampleFile     string        // CSV path for periodic throughput/latency samples
 
 	// conflict distribution
 	Conflicts int // percentage of conflicting keys
@@ -56,6 +84,18 @@ func DefaultBConfig() Bconfig {
 		Concurrency:          1,
 		Distribution:         "uniform",
 		LinearizabilityCheck: true,
+		DeletePercentage:     0,
+		SequentialRead:       false,
+		BenchMode:            false,
+		Name:                 "BigBFT",
+		Workload:             "",
+		Seed:                 0,
+		CPUProfile:           "",
+		MemProfile:           "",
+		TraceFile:            "",
+		MaxCPU:               0,
+		SampleInterval:       0,
+		SampleFile:           "",
 		Conflicts:            100,
 		Min:                  0,
 		Mu:                   0,
@@ -72,67 +112,100 @@ func DefaultBConfig() Bconfig {
 type Benchmark struct {
 	db DB // read/write operation interface
 	Bconfig
-	//*History
-
-	rate      *Limiter
-	latency   []time.Duration // latency per operation
-	startTime time.Time
-	zipf      *rand.Zipf
-	counter   int
-	wait sync.WaitGroup // waiting for all generated keys to complete
-	globalCouner int
+	*History
+
+	rate         *Limiter
+	latency      []time.Duration // latency per operation
+	latencyMu    sync.Mutex      // guards latency against the sampler goroutine
+	startTime    time.Time
+	wait         sync.WaitGroup // waiting for all issued operations to complete
+	readIndex    int64          // next offset into [Min, Min+K) handed out by SequentialRead
+	writeCounter int64          // shared atomic counter; each Write gets the incremented value as Globalcounter
 }
 // NewBenchmark returns new Benchmark object given implementation of DB interface
 func NewBenchmark(db DB) *Benchmark {
 	b := new(Benchmark)
 	b.db = db
-	b.counter = -1
-	b.globalCouner = -1
+	b.writeCounter = -1
 	b.Bconfig = config.Benchmark
-	//b.History = NewHistory()
+	b.History = NewHistory()
 	if b.Throttle > 0 {
 		b.rate = NewLimiter(b.Throttle)
 	}
-	rand.Seed(time.Now().UTC().UnixNano())
-	r := rand.New(rand.NewSource(time.Now().UTC().UnixNano()))
-	b.zipf = rand.NewZipf(r, b.ZipfianS, b.ZipfianV, uint64(b.K))
+	if b.DeletePercentage > 0 {
+		if _, ok := b.db.(Deleter); !ok {
+			log.Fatalf("DeletePercentage > 0 but DB does not implement Deleter")
+		}
+	}
+	if b.Seed == 0 {
+		b.Seed = time.Now().UTC().UnixNano()
+		log.Infof("no Seed configured, using %d", b.Seed)
+	}
 	return b
 }
-// Load will create all K keys to DB
+
+// seedFor derives worker id's rand seed from the run's base Seed, so a
+// run is fully reproducible by pinning Bconfig.Seed but no two workers
+// share a stream.
+func (b *Benchmark) seedFor(id int) int64 {
+	return b.Seed + int64(id)*2654435761 // arbitrary odd constant, just for decorrelation
+}
+// Load will create all K keys to DB, splitting [Min, Min+K) into one
+// contiguous range per worker so workers don't share a keys channel.
+// Latencies still go through one shared channel and writes still bump
+// the shared atomic writeCounter.
 func (b *Benchmark) Load() {
 	b.W = 1.0
 	b.Throttle = 0
 
 	b.db.Init()
-	// Buffered Channels
-	keys := make(chan int, b.Concurrency)
 	latencies := make(chan time.Duration, 1000)
-	globalCouner := make(chan int, 0)
-
 	defer close(latencies)
 	go b.collect(latencies)
 
 	b.startTime = time.Now()
+	var workers sync.WaitGroup
+	chunk := b.K / b.Concurrency
 	for i := 0; i < b.Concurrency; i++ {
-		go b.worker(keys, latencies,globalCouner)
-	}
-	for i := b.Min; i < b.Min+b.K; i++ {
-		b.wait.Add(1)
-		keys <- i
-		//b.globalCouner++
-		globalCouner <- b.globalCouner
+		lo := b.Min + i*chunk
+		hi := lo + chunk
+		if i == b.Concurrency-1 {
+			hi = b.Min + b.K
+		}
+		workers.Add(1)
+		go func(lo, hi int) {
+			defer workers.Done()
+			b.loadRange(lo, hi, latencies)
+		}(lo, hi)
 	}
+	workers.Wait()
+	b.wait.Wait()
 	t := time.Now().Sub(b.startTime)
 
 	b.db.Stop()
-	close(keys)
-	b.wait.Wait()
 	stat := Statistic(b.latency)
 
 	log.Infof("Benchmark took %v\n", t)
 	log.Infof("Throughput %f\n", float64(len(b.latency))/t.Seconds())
 	log.Info(stat)
 }
+
+// loadRange writes keys [lo, hi) to the DB.
+func (b *Benchmark) loadRange(lo, hi int, result chan<- time.Duration) {
+	for k := lo; k < hi; k++ {
+		b.wait.Add(1)
+		v := GenerateRandVal(b.Bconfig.Size)
+		s := time.Now()
+		err := b.db.Write(k, v, int(atomic.AddInt64(&b.writeCounter, 1)))
+		e := time.Now()
+		if err == nil {
+			result <- e.Sub(s)
+		} else {
+			log.Error(err)
+			b.wait.Done()
+		}
+	}
+}
 // Run starts the main logic of benchmarking
 func (b *Benchmark) Run() {
 	var stop chan bool
@@ -144,52 +217,59 @@ func (b *Benchmark) Run() {
 	}
 
 	b.latency = make([]time.Duration, 0)
-	keys := make(chan int, b.Concurrency)
 	latencies := make(chan time.Duration, 1000)
-
-	globalCouner := make(chan int, 0)
-
 	defer close(latencies)
 	go b.collect(latencies)
 
-	// number of threads or concurrency
-	for i := 0; i < b.Concurrency; i++ {
-		// this b is object calls worker function
-		go func() {
-			b.worker(keys,latencies,globalCouner)
-		}()
-	}
+	profiling := b.startProfiling()
+
+	var workers sync.WaitGroup
 	b.db.Init()
 	b.startTime = time.Now()
+
+	sampleDone := make(chan struct{})
+	go b.sampleThroughput(sampleDone)
+
 	if b.T > 0 {
-		timer := time.NewTimer(time.Second * time.Duration(b.T))
-	loop:
-		for {
-			select {
-			case <-timer.C:
-				break loop
-			default:
-				b.wait.Add(1)
-				keys <- b.next()
-				b.globalCouner++
-				globalCouner <- b.globalCouner
-			}
+		done := make(chan struct{})
+		for i := 0; i < b.Concurrency; i++ {
+			workers.Add(1)
+			go func(id int) {
+				defer workers.Done()
+				b.worker(id, 0, done, latencies)
+			}(i)
 		}
+		time.Sleep(time.Second * time.Duration(b.T))
+		close(done)
 	} else {
-		for i := 0; i < b.N; i++ {
-			b.wait.Add(1)
-			keys <- b.next()
-			b.globalCouner++
-			globalCouner <- b.globalCouner
+		perWorker := b.N / b.Concurrency
+		remainder := b.N % b.Concurrency
+		for i := 0; i < b.Concurrency; i++ {
+			ops := perWorker
+			if i < remainder {
+				ops++
+			}
+			workers.Add(1)
+			go func(id, ops int) {
+				defer workers.Done()
+				b.worker(id, ops, nil, latencies)
+			}(i, ops)
 		}
-		b.wait.Wait()
 	}
+	workers.Wait()
+	b.wait.Wait()
+	close(sampleDone)
 	t := time.Now().Sub(b.startTime)
 
 	b.db.Stop()
-	close(keys)
-	close(globalCouner)
+	b.stopProfiling(profiling)
 	log.Debugf("--------------------done -------------2")
+
+	if b.BenchMode {
+		NewPercentileReport(b.latency).WriteGoBenchFormat(b.Name)
+		return
+	}
+
 	stat := Statistic(b.latency)
 	log.Infof("Concurrency = %d", b.Concurrency)
 	log.Infof("Write Ratio = %f", b.W)
@@ -199,85 +279,134 @@ func (b *Benchmark) Run() {
 	log.Info(stat)
 
 	stat.WriteFile("latency")
-	//b.History.WriteFile("history")
-}
-// generates key based on distribution
-func (b *Benchmark) next() int {
-	var key int
-	switch b.Distribution {
-	case "order":
-		b.counter = (b.counter + 1) % b.K
-		key = b.counter + b.Min
-
-	case "uniform":
-		key = rand.Intn(b.K) + b.Min
-
-	case "conflict":
-		if rand.Intn(100) < b.Conflicts {
-			key = 0
+	if err := b.History.WriteFile("history"); err != nil {
+		log.Error(err)
+	}
+
+	if b.LinearizabilityCheck {
+		if ok, key := NewLinearizabilityChecker(b.History).Check(); !ok {
+			log.Errorf("history is not linearizable, first bad key = %d", key)
 		} else {
-			b.counter = (b.counter + 1) % b.K
-			key = b.counter + b.Min
+			log.Infof("history is linearizable")
 		}
+	}
+}
+// nextSequentialReadKey hands out the next key in [Min, Min+K) so that
+// SequentialRead benchmarks get deterministic, comparable hit rates.
+func (b *Benchmark) nextSequentialReadKey() int {
+	n := atomic.AddInt64(&b.readIndex, 1) - 1
+	return b.Min + int(n%int64(b.K))
+}
 
-	case "normal":
-		key = int(rand.NormFloat64()*b.Sigma + b.Mu)
-		for key < 0 {
-			key += b.K
+// worker is a single simulated client. It owns its key generator and
+// rand source end to end so Concurrency workers never contend on global
+// rand state or a shared keys channel. ops > 0 runs exactly that many
+// operations (b.N mode); ops == 0 runs until done is closed (b.T mode).
+func (b *Benchmark) worker(id int, ops int, done <-chan struct{}, result chan<- time.Duration) {
+	r := rand.New(rand.NewSource(b.seedFor(id)))
+	kg := newKeyGen(&b.Bconfig, r, id, b.Concurrency)
+	wl := newWorkload(&b.Bconfig)
+
+	for issued := 0; ops == 0 || issued < ops; issued++ {
+		if ops == 0 {
+			select {
+			case <-done:
+				return
+			default:
+			}
 		}
-		for key > b.K {
-			key -= b.K
+
+		if b.Throttle > 0 {
+			b.rate.Wait()
 		}
 
-	case "zipfan":
-		key = int(b.zipf.Uint64())
+		k := kg.Next()
+		op := new(operation)
+		op.ClientID = id
+		op.Key = k
 
-	case "exponential":
-		key = int(rand.ExpFloat64() / b.Lambda)
+		var opType OpType
+		if wl != nil {
+			opType = wl.Op(r, k).Type
+		} else {
+			x := r.Float64()
+			switch {
+			case x < b.W:
+				opType = OpWrite
+			case x < b.W+b.DeletePercentage:
+				opType = OpDelete
+			default:
+				opType = OpRead
+			}
+		}
 
-	default:
-		log.Fatalf("unknown distribution %s", b.Distribution)
-	}
+		var s, e time.Time
+		var err error
+		var v []byte
+		switch opType {
+		case OpWrite:
+			op.Op = "write"
+			v = GenerateRandVal(b.Bconfig.Size)
+			s = time.Now()
+			err = b.db.Write(k, v, int(atomic.AddInt64(&b.writeCounter, 1)))
+			e = time.Now()
+			op.Input = v
 
-	if b.Throttle > 0 {
-		b.rate.Wait()
-	}
+		case OpDelete:
+			op.Op = "delete"
+			del, ok := b.db.(Deleter)
+			s = time.Now()
+			if ok {
+				err = del.Delete(k)
+			} else {
+				err = errNoDeleter
+			}
+			e = time.Now()
 
-	return key
-}
-// this where client do the work from benchmark
-func (b *Benchmark) worker(keys <-chan int, result chan<- time.Duration, globalCouner <- chan int) {
-	var s time.Time
-	var e time.Time
-	var err error
-	var v []byte
-	//data := make([]byte, 4)
-	for k := range keys {
-		op := new(operation)
-		if rand.Float64() < b.W {
-			v = GenerateRandVal(b.Bconfig.Size)
+		case OpReadModifyWrite:
+			op.Op = "read-modify-write"
 			s = time.Now()
-			err = b.db.Write(k, v,<- globalCouner)
+			op.Output, err = b.db.Read(k)
+			if err == nil {
+				v = GenerateRandVal(b.Bconfig.Size)
+				err = b.db.Write(k, v, int(atomic.AddInt64(&b.writeCounter, 1)))
+			}
 			e = time.Now()
-			op.input = v
-		} else {
+			op.Input = v
+
+		default: // OpRead
+			op.Op = "read"
+			readKey := k
+			if b.SequentialRead {
+				readKey = b.nextSequentialReadKey()
+			}
+			op.Key = readKey
 			s = time.Now()
+			v, err = b.db.Read(readKey)
 			e = time.Now()
-			op.output = v
+			op.Output = v
 		}
-		op.start = s.Sub(b.startTime).Nanoseconds()
+
+		op.Start = s.Sub(b.startTime).Nanoseconds()
+		b.wait.Add(1)
 		if err == nil {
-			op.end = e.Sub(b.startTime).Nanoseconds()
+			op.End = e.Sub(b.startTime).Nanoseconds()
 			result <- e.Sub(s)
+			if b.LinearizabilityCheck {
+				b.History.Add(*op)
+			}
 		} else {
-			op.end = math.MaxInt64
+			op.End = math.MaxInt64
 			log.Error(err)
+			b.wait.Done()
 		}
 	}
 }
 func (b *Benchmark) collect(latencies <-chan time.Duration) {
 	for t := range latencies {
+		b.latencyMu.Lock()
 		b.latency = append(b.latency, t)
+		b.latencyMu.Unlock()
 		b.wait.Done()
 	}
 	log.Debugf("time = %v", b.latency)
@@ -0,0 +1,119 @@
+package BigBFT
+
+import "math/rand"
+
+// partitionRange splits [min, min+k) into n contiguous, disjoint chunks
+// and returns worker id's chunk, the same way Load() splits the keyspace
+// across its loadRange workers. Stateful/cyclic KeyGens use this so Concurrency workers
+// spread out over the keyspace instead of each independently replaying
+// the whole cycle (which would pile every worker onto the same keys at
+// the same time).
+func partitionRange(min, k, id, n int) (lo, hi int) {
+	chunk := k / n
+	lo = min + id*chunk
+	hi = lo + chunk
+	if id == n-1 {
+		hi = min + k
+	}
+	return lo, hi
+}
+
+// orderKeyGen hands out lo, lo+1, ... wrapping at hi, where [lo, hi) is
+// this worker's partition of [Min, Min+K) (the "order" distribution). It
+// has no randomness, so it needs no rand.Rand of its own.
+type orderKeyGen struct {
+	lo, hi, cur int
+}
+
+func newOrderKeyGen(cfg *Bconfig, id, n int) *orderKeyGen {
+	lo, hi := partitionRange(cfg.Min, cfg.K, id, n)
+	return &orderKeyGen{lo: lo, hi: hi, cur: lo}
+}
+
+func (g *orderKeyGen) Next() int {
+	key := g.cur
+	g.cur++
+	if g.cur >= g.hi {
+		g.cur = g.lo
+	}
+	return key
+}
+
+// uniformKeyGen picks uniformly over [Min, Min+K) using its own rand
+// source, so concurrent workers never contend on the global one.
+type uniformKeyGen struct {
+	cfg *Bconfig
+	r   *rand.Rand
+}
+
+func (g *uniformKeyGen) Next() int {
+	return g.r.Intn(g.cfg.K) + g.cfg.Min
+}
+
+// conflictKeyGen returns key 0 Conflicts% of the time (a deliberate,
+// shared hotspot across all workers) and otherwise walks this worker's
+// own partition of the key space in order, so the "non-conflicting"
+// traffic doesn't itself become an accidental hotspot.
+type conflictKeyGen struct {
+	cfg         *Bconfig
+	r           *rand.Rand
+	lo, hi, cur int
+}
+
+func newConflictKeyGen(cfg *Bconfig, r *rand.Rand, id, n int) *conflictKeyGen {
+	lo, hi := partitionRange(cfg.Min, cfg.K, id, n)
+	return &conflictKeyGen{cfg: cfg, r: r, lo: lo, hi: hi, cur: lo}
+}
+
+func (g *conflictKeyGen) Next() int {
+	if g.r.Intn(100) < g.cfg.Conflicts {
+		return 0
+	}
+	key := g.cur
+	g.cur++
+	if g.cur >= g.hi {
+		g.cur = g.lo
+	}
+	return key
+}
+
+// normalKeyGen samples a Gaussian centered on cfg.Mu. cfg is a pointer so
+// it tracks Benchmark.Run's Move schedule, which mutates Mu live.
+type normalKeyGen struct {
+	cfg *Bconfig
+	r   *rand.Rand
+}
+
+func (g *normalKeyGen) Next() int {
+	key := int(g.r.NormFloat64()*g.cfg.Sigma + g.cfg.Mu)
+	for key < 0 {
+		key += g.cfg.K
+	}
+	for key > g.cfg.K {
+		key -= g.cfg.K
+	}
+	return key
+}
+
+// zipfKeyGen wraps math/rand.Zipf over the worker's own rand source.
+type zipfKeyGen struct {
+	zipf *rand.Zipf
+}
+
+func newZipfKeyGen(cfg *Bconfig, r *rand.Rand) KeyGen {
+	return &zipfKeyGen{zipf: rand.NewZipf(r, cfg.ZipfianS, cfg.ZipfianV, uint64(cfg.K))}
+}
+
+func (g *zipfKeyGen) Next() int {
+	return int(g.zipf.Uint64())
+}
+
+// exponentialKeyGen samples an exponential distribution scaled by Lambda.
+type exponentialKeyGen struct {
+	cfg *Bconfig
+	r   *rand.Rand
+}
+
+func (g *exponentialKeyGen) Next() int {
+	return int(g.r.ExpFloat64() / g.cfg.Lambda)
+}
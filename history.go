@@ -0,0 +1,55 @@
+package BigBFT
+
+import (
+	"encoding/gob"
+	"os"
+	"sync"
+)
+
+// operation is a single recorded client operation: its key, the request
+// and response bytes, and the [start,end] interval (nanoseconds since
+// Benchmark.startTime) during which it was outstanding. Fields are
+// exported so History.WriteFile can gob-encode them: gob skips
+// unexported fields entirely, and a struct with none of them fails to
+// encode at all.
+type operation struct {
+	ClientID int
+	Key      int
+	Op       string // "read", "write", "delete" or "read-modify-write"
+	Input    []byte
+	Output   []byte
+	Start    int64
+	End      int64
+}
+
+// History is the per-key operation log collected during a run. It backs
+// the linearizability checker: LinearizabilityCheck only has something to
+// verify once operations are actually recorded here.
+type History struct {
+	mu  sync.Mutex
+	ops map[int][]operation // key -> operations on that key, unsorted
+}
+
+// NewHistory returns an empty History.
+func NewHistory() *History {
+	return &History{ops: make(map[int][]operation)}
+}
+
+// Add records op against its key.
+func (h *History) Add(op operation) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.ops[op.Key] = append(h.ops[op.Key], op)
+}
+
+// WriteFile gob-encodes the recorded history to name.
+func (h *History) WriteFile(name string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return gob.NewEncoder(f).Encode(h.ops)
+}
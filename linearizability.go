@@ -0,0 +1,103 @@
+package BigBFT
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/salemmohammed/BigBFT/log"
+)
+
+// LinearizabilityChecker verifies a History against single-register
+// linearizability, one key at a time: does some total order of the
+// operations on that key, consistent with each operation's [start,end]
+// interval, explain every read's observed value?
+type LinearizabilityChecker struct {
+	h *History
+}
+
+// NewLinearizabilityChecker returns a checker over h.
+func NewLinearizabilityChecker(h *History) *LinearizabilityChecker {
+	return &LinearizabilityChecker{h: h}
+}
+
+// Check runs the checker over every key in the history. It returns true
+// and no key on success, or false and the first offending key otherwise,
+// having already logged the offending sub-history.
+func (c *LinearizabilityChecker) Check() (bool, int) {
+	keys := make([]int, 0, len(c.h.ops))
+	for k := range c.h.ops {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+
+	for _, k := range keys {
+		if !linearizableRegister(c.h.ops[k]) {
+			log.Errorf("linearizability violation on key %d", k)
+			for _, op := range c.h.ops[k] {
+				log.Errorf("  %s", describeOp(op))
+			}
+			return false, k
+		}
+	}
+	return true, 0
+}
+
+func describeOp(op operation) string {
+	return fmt.Sprintf("client=%d type=%s start=%d end=%d in=%v out=%v",
+		op.ClientID, op.Op, op.Start, op.End, op.Input, op.Output)
+}
+
+// linearizableRegister decides whether ops (all touching one key) admit a
+// linearization. It sorts by end time, then repeatedly tries to commit any
+// pending op (one that has started but isn't yet linearized) whose
+// commit keeps every already-completed read consistent with the last
+// written value, backtracking on dead ends.
+func linearizableRegister(ops []operation) bool {
+	sorted := make([]operation, len(ops))
+	copy(sorted, ops)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].End < sorted[j].End })
+
+	done := make([]bool, len(sorted))
+	var search func(linearized int, lastValue []byte) bool
+	search = func(linearized int, lastValue []byte) bool {
+		if linearized == len(sorted) {
+			return true
+		}
+		for i, op := range sorted {
+			if done[i] {
+				continue
+			}
+			// op may only be linearized next if nothing not-yet-linearized
+			// ends strictly before it starts (real-time order).
+			ready := true
+			for j, other := range sorted {
+				if !done[j] && j != i && other.End <= op.Start && other.End < op.Start {
+					ready = false
+					break
+				}
+			}
+			if !ready {
+				continue
+			}
+			isRead := op.Op == "read" || op.Op == "read-modify-write"
+			if isRead && !bytes.Equal(op.Output, lastValue) {
+				continue
+			}
+			next := lastValue
+			switch op.Op {
+			case "write", "read-modify-write":
+				next = op.Input
+			case "delete":
+				next = nil
+			}
+			done[i] = true
+			if search(linearized+1, next) {
+				return true
+			}
+			done[i] = false
+		}
+		return false
+	}
+	return search(0, nil)
+}
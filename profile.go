@@ -0,0 +1,132 @@
+package BigBFT
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+	"strconv"
+	"time"
+
+	"github.com/salemmohammed/BigBFT/log"
+)
+
+// profileHandle tracks the profiling artifacts a run opened, so Run can
+// close/stop them in the right order once the workload is done.
+type profileHandle struct {
+	cpuFile   *os.File
+	traceFile *os.File
+}
+
+// startProfiling applies Bconfig's profiling knobs: GOMAXPROCS, a CPU
+// profile and an execution trace, both started before the timed portion
+// of the run so their output covers the whole thing.
+func (b *Benchmark) startProfiling() *profileHandle {
+	if b.MaxCPU > 0 {
+		runtime.GOMAXPROCS(b.MaxCPU)
+	}
+
+	h := &profileHandle{}
+	if b.CPUProfile != "" {
+		f, err := os.Create(b.CPUProfile)
+		if err != nil {
+			log.Error(err)
+		} else if err := pprof.StartCPUProfile(f); err != nil {
+			log.Error(err)
+			f.Close()
+		} else {
+			h.cpuFile = f
+		}
+	}
+	if b.TraceFile != "" {
+		f, err := os.Create(b.TraceFile)
+		if err != nil {
+			log.Error(err)
+		} else if err := trace.Start(f); err != nil {
+			log.Error(err)
+			f.Close()
+		} else {
+			h.traceFile = f
+		}
+	}
+	return h
+}
+
+// stopProfiling stops whatever startProfiling started and, if
+// MemProfile is set, writes a heap profile.
+func (b *Benchmark) stopProfiling(h *profileHandle) {
+	if h.cpuFile != nil {
+		pprof.StopCPUProfile()
+		h.cpuFile.Close()
+	}
+	if h.traceFile != nil {
+		trace.Stop()
+		h.traceFile.Close()
+	}
+	if b.MemProfile == "" {
+		return
+	}
+	f, err := os.Create(b.MemProfile)
+	if err != nil {
+		log.Error(err)
+		return
+	}
+	defer f.Close()
+	runtime.GC()
+	if err := pprof.WriteHeapProfile(f); err != nil {
+		log.Error(err)
+	}
+}
+
+// sampleThroughput polls b.latency every SampleInterval and appends a
+// {t, throughput, p50, p99} row to SampleFile, so a run's tail latency
+// can be correlated with GC pauses or leader changes instead of only
+// seeing the final aggregate.
+func (b *Benchmark) sampleThroughput(done <-chan struct{}) {
+	if b.SampleInterval <= 0 || b.SampleFile == "" {
+		return
+	}
+	f, err := os.Create(b.SampleFile)
+	if err != nil {
+		log.Error(err)
+		return
+	}
+	defer f.Close()
+	w := csv.NewWriter(f)
+	defer w.Flush()
+	w.Write([]string{"t_seconds", "throughput_ops_sec", "p50_ns", "p99_ns"})
+
+	ticker := time.NewTicker(b.SampleInterval)
+	defer ticker.Stop()
+
+	lastN := 0
+	lastT := b.startTime
+	for {
+		select {
+		case <-done:
+			return
+		case now := <-ticker.C:
+			b.latencyMu.Lock()
+			recent := append([]time.Duration(nil), b.latency[lastN:]...)
+			n := len(b.latency)
+			b.latencyMu.Unlock()
+
+			throughput := 0.0
+			if dt := now.Sub(lastT).Seconds(); dt > 0 {
+				throughput = float64(n-lastN) / dt
+			}
+			report := NewPercentileReport(recent)
+			w.Write([]string{
+				fmt.Sprintf("%.3f", now.Sub(b.startTime).Seconds()),
+				fmt.Sprintf("%.2f", throughput),
+				strconv.FormatInt(report.P50.Nanoseconds(), 10),
+				strconv.FormatInt(report.P99.Nanoseconds(), 10),
+			})
+			w.Flush()
+
+			lastN, lastT = n, now
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package BigBFT
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// PercentileReport is a tail-latency summary over a run's collected
+// latencies, suitable for diffing across commits or cluster sizes.
+type PercentileReport struct {
+	N     int
+	Mean  time.Duration
+	P50   time.Duration
+	P90   time.Duration
+	P99   time.Duration
+	P999  time.Duration
+	P9999 time.Duration
+	Worst time.Duration
+}
+
+// percentile returns the value at the given fraction (0-1) of a sorted
+// latency slice, clamping to the last element.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := int(p * float64(len(sorted)))
+	if idx >= len(sorted) {
+		idx = len(sorted) - 1
+	}
+	return sorted[idx]
+}
+
+// NewPercentileReport computes p50/p90/p99/p99.9/p99.99/worst/mean over
+// latency. latency is not mutated.
+func NewPercentileReport(latency []time.Duration) PercentileReport {
+	sorted := make([]time.Duration, len(latency))
+	copy(sorted, latency)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var sum time.Duration
+	for _, d := range sorted {
+		sum += d
+	}
+	var mean time.Duration
+	if len(sorted) > 0 {
+		mean = sum / time.Duration(len(sorted))
+	}
+
+	r := PercentileReport{
+		N:     len(sorted),
+		Mean:  mean,
+		P50:   percentile(sorted, 0.50),
+		P90:   percentile(sorted, 0.90),
+		P99:   percentile(sorted, 0.99),
+		P999:  percentile(sorted, 0.999),
+		P9999: percentile(sorted, 0.9999),
+	}
+	if len(sorted) > 0 {
+		r.Worst = sorted[len(sorted)-1]
+	}
+	return r
+}
+
+// WriteGoBenchFormat prints the report as Benchmark<name>_<stat> lines
+// (mean, p50, p90, p99, p999, p9999, worst) in the standard
+// `Benchmark<Name>\t<N>\t<ns> ns/op` format so results can be fed
+// straight into benchstat.
+func (r PercentileReport) WriteGoBenchFormat(name string) {
+	print := func(suffix string, d time.Duration) {
+		fmt.Printf("Benchmark%s_%s\t%d\t%d ns/op\n", name, suffix, r.N, d.Nanoseconds())
+	}
+	print("mean", r.Mean)
+	print("p50", r.P50)
+	print("p90", r.P90)
+	print("p99", r.P99)
+	print("p999", r.P999)
+	print("p9999", r.P9999)
+	print("worst", r.Worst)
+}
@@ -0,0 +1,118 @@
+package BigBFT
+
+import (
+	"math/rand"
+
+	"github.com/salemmohammed/BigBFT/log"
+)
+
+// KeyGen produces the next key to operate on. It replaces the hard-coded
+// switch that used to live in Benchmark.next(), so a custom distribution
+// can be plugged in without editing benchmark.go.
+type KeyGen interface {
+	Next() int
+}
+
+// OpType enumerates the kinds of operation a Workload can ask for.
+type OpType int
+
+const (
+	OpRead OpType = iota
+	OpWrite
+	OpDelete
+	OpReadModifyWrite
+)
+
+// Operation is one unit of work a Workload produces for a given key.
+type Operation struct {
+	Type OpType
+	Key  int
+}
+
+// Workload decides what kind of operation to run against key. Built-ins
+// cover the YCSB core workloads (A/B/C/D/F); custom mixes (batched puts,
+// conditional writes, ...) can be registered with RegisterWorkload.
+type Workload interface {
+	Op(r *rand.Rand, key int) Operation
+}
+
+var keyGenRegistry = map[string]func(*Bconfig, *rand.Rand, int, int) KeyGen{}
+var workloadRegistry = map[string]func(*Bconfig) Workload{}
+
+// RegisterKeyGen makes a named key-generation strategy available to
+// Bconfig.Distribution. factory is handed the *rand.Rand the calling
+// worker owns (so the resulting KeyGen never touches shared rand state)
+// plus that worker's id and the total worker count, so stateful/cyclic
+// generators can partition the keyspace into disjoint per-worker ranges
+// instead of every worker replaying the same cycle.
+func RegisterKeyGen(name string, factory func(cfg *Bconfig, r *rand.Rand, id, n int) KeyGen) {
+	keyGenRegistry[name] = factory
+}
+
+// RegisterWorkload makes a named operation mix available to
+// Bconfig.Workload.
+func RegisterWorkload(name string, factory func(*Bconfig) Workload) {
+	workloadRegistry[name] = factory
+}
+
+// newKeyGen resolves cfg.Distribution through the registry. cfg is kept
+// as a pointer so generators (e.g. the moving-average normal generator)
+// observe live updates such as the Mu field mutated by Benchmark.Run's
+// Move schedule. r is the caller's own per-worker rand source; id and n
+// are that worker's index and Concurrency.
+func newKeyGen(cfg *Bconfig, r *rand.Rand, id, n int) KeyGen {
+	factory, ok := keyGenRegistry[cfg.Distribution]
+	if !ok {
+		log.Fatalf("unknown distribution %s", cfg.Distribution)
+	}
+	return factory(cfg, r, id, n)
+}
+
+// newWorkload resolves cfg.Workload through the registry, or returns nil
+// if no workload was configured (callers fall back to the plain
+// write/read/delete-ratio dispatch).
+func newWorkload(cfg *Bconfig) Workload {
+	if cfg.Workload == "" {
+		return nil
+	}
+	factory, ok := workloadRegistry[cfg.Workload]
+	if !ok {
+		log.Fatalf("unknown workload %s", cfg.Workload)
+	}
+	return factory(cfg)
+}
+
+func init() {
+	RegisterKeyGen("order", func(cfg *Bconfig, r *rand.Rand, id, n int) KeyGen { return newOrderKeyGen(cfg, id, n) })
+	RegisterKeyGen("uniform", func(cfg *Bconfig, r *rand.Rand, id, n int) KeyGen { return &uniformKeyGen{cfg: cfg, r: r} })
+	RegisterKeyGen("conflict", func(cfg *Bconfig, r *rand.Rand, id, n int) KeyGen { return newConflictKeyGen(cfg, r, id, n) })
+	RegisterKeyGen("normal", func(cfg *Bconfig, r *rand.Rand, id, n int) KeyGen { return &normalKeyGen{cfg: cfg, r: r} })
+	RegisterKeyGen("zipfan", func(cfg *Bconfig, r *rand.Rand, id, n int) KeyGen { return newZipfKeyGen(cfg, r) })
+	RegisterKeyGen("exponential", func(cfg *Bconfig, r *rand.Rand, id, n int) KeyGen { return &exponentialKeyGen{cfg: cfg, r: r} })
+
+	RegisterWorkload("ycsb-a", func(cfg *Bconfig) Workload { return ycsbWorkload{write: 0.5} })
+	RegisterWorkload("ycsb-b", func(cfg *Bconfig) Workload { return ycsbWorkload{write: 0.05} })
+	RegisterWorkload("ycsb-c", func(cfg *Bconfig) Workload { return ycsbWorkload{write: 0} })
+	RegisterWorkload("ycsb-d", func(cfg *Bconfig) Workload { return ycsbWorkload{write: 0.05} })
+	RegisterWorkload("ycsb-f", func(cfg *Bconfig) Workload { return ycsbWorkload{rmw: 0.5} })
+}
+
+// ycsbWorkload implements the YCSB core read/update/read-modify-write
+// mixes (A, B, C, D and F differ only in their ratios; D's "latest"
+// read skew comes from pairing it with a KeyGen, not from the workload).
+type ycsbWorkload struct {
+	write float64
+	rmw   float64
+}
+
+func (w ycsbWorkload) Op(r *rand.Rand, key int) Operation {
+	x := r.Float64()
+	switch {
+	case x < w.write:
+		return Operation{Type: OpWrite, Key: key}
+	case x < w.write+w.rmw:
+		return Operation{Type: OpReadModifyWrite, Key: key}
+	default:
+		return Operation{Type: OpRead, Key: key}
+	}
+}